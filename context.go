@@ -0,0 +1,46 @@
+package prometheus
+
+import (
+	"context"
+
+	"go.unistack.org/micro/v3/metadata"
+)
+
+type contextLabelsKey struct{}
+
+// WithLabels stashes label values in ctx for the keys configured via the
+// Meter's ContextLabels option, so they can be appended as additional label
+// values on every counter/histogram/summary vector.
+func WithLabels(ctx context.Context, labels map[string]string) context.Context {
+	return context.WithValue(ctx, contextLabelsKey{}, labels)
+}
+
+func labelsFromContext(ctx context.Context) (map[string]string, bool) {
+	labels, ok := ctx.Value(contextLabelsKey{}).(map[string]string)
+	return labels, ok
+}
+
+// contextLabelValues resolves m.contextLabels, in order, against the labels
+// stashed via WithLabels and, failing that, the incoming request metadata.
+func (m *Meter) contextLabelValues(ctx context.Context) []string {
+	if len(m.contextLabels) == 0 {
+		return nil
+	}
+
+	values := make([]string, len(m.contextLabels))
+
+	stashed, _ := labelsFromContext(ctx)
+	md, _ := metadata.FromContext(ctx)
+
+	for i, key := range m.contextLabels {
+		if v, ok := stashed[key]; ok {
+			values[i] = v
+			continue
+		}
+		if v, ok := md.Get(key); ok {
+			values[i] = v
+		}
+	}
+
+	return values
+}
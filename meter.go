@@ -0,0 +1,273 @@
+package prometheus
+
+import (
+	"context"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.unistack.org/micro/v3/logger"
+)
+
+var (
+	// DefaultHistogramBuckets are the histogram buckets used by a Meter
+	// unless overridden via HistogramBuckets.
+	DefaultHistogramBuckets = prometheus.DefBuckets
+	// DefaultSummaryObjectives are the summary objectives used by a Meter
+	// unless overridden via SummaryObjectives.
+	DefaultSummaryObjectives = map[float64]float64{0.5: 0.05, 0.9: 0.01, 0.99: 0.001}
+
+	// DefaultLagBuckets are the buckets used for subscribe_message_lag_seconds.
+	// The lag is computed from a timestamp stamped by the publish wrapper and
+	// compared against wall-clock time on the subscriber, so clock skew
+	// between the two hosts can produce negative observations; these buckets
+	// span negative values so that skew is visible instead of silently
+	// collapsing into the smallest positive HistogramBuckets bucket.
+	DefaultLagBuckets = []float64{-60, -10, -5, -1, -0.5, -0.1, 0, 0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600}
+
+	defaultMeter     *Meter
+	defaultMeterOnce sync.Once
+)
+
+// defaultMeterInstance returns the package-level Meter backing the
+// top-level New*Wrapper functions when no Meter option is supplied,
+// registered against the default registry (see Registry).
+func defaultMeterInstance() *Meter {
+	defaultMeterOnce.Do(func() {
+		defaultMeter = NewMeter()
+	})
+	return defaultMeter
+}
+
+// MeterOptions holds construction options for a Meter.
+type MeterOptions struct {
+	Registry          *prometheus.Registry
+	HistogramBuckets  []float64
+	SummaryObjectives map[float64]float64
+	MetricPrefix      string
+	LabelPrefix       string
+	ContextLabels     []string
+}
+
+// MeterOption func signature
+type MeterOption func(*MeterOptions)
+
+// Registry sets the registry a Meter's collectors are registered
+// against. Defaults to prometheus.DefaultRegisterer when it is a
+// *prometheus.Registry, and to a new registry otherwise.
+func Registry(r *prometheus.Registry) MeterOption {
+	return func(o *MeterOptions) {
+		o.Registry = r
+	}
+}
+
+// HistogramBuckets overrides the buckets used by all duration histograms.
+func HistogramBuckets(buckets []float64) MeterOption {
+	return func(o *MeterOptions) {
+		o.HistogramBuckets = buckets
+	}
+}
+
+// SummaryObjectives overrides the objectives used by all latency summaries.
+func SummaryObjectives(objectives map[float64]float64) MeterOption {
+	return func(o *MeterOptions) {
+		o.SummaryObjectives = objectives
+	}
+}
+
+// MetricPrefix overrides the prefix prepended to every metric name.
+func MetricPrefix(prefix string) MeterOption {
+	return func(o *MeterOptions) {
+		o.MetricPrefix = prefix
+	}
+}
+
+// LabelPrefix overrides the prefix prepended to every label name.
+func LabelPrefix(prefix string) MeterOption {
+	return func(o *MeterOptions) {
+		o.LabelPrefix = prefix
+	}
+}
+
+// ContextLabels declares extra label names, read from the context passed to
+// each wrapper call (see WithLabels and metadata.FromContext), that are
+// appended to every counter/histogram/summary vector. This lets callers
+// partition metrics by tenant ID, route, caller service, etc.
+func ContextLabels(keys ...string) MeterOption {
+	return func(o *MeterOptions) {
+		o.ContextLabels = append(o.ContextLabels, keys...)
+	}
+}
+
+// Meter holds the collectors used by the wrappers in this package,
+// registered against its own *prometheus.Registry. This allows running
+// several wrapped services in the same binary, unit-testing the wrappers
+// in isolation, and customizing histogram buckets / summary objectives
+// without touching prometheus.DefaultRegisterer.
+type Meter struct {
+	registry      *prometheus.Registry
+	contextLabels []string
+
+	clientOpsCounter           *prometheus.CounterVec
+	clientTimeCounterSummary   *prometheus.SummaryVec
+	clientTimeCounterHistogram *prometheus.HistogramVec
+	clientRequestInflight      *prometheus.GaugeVec
+
+	serverOpsCounter           *prometheus.CounterVec
+	serverTimeCounterSummary   *prometheus.SummaryVec
+	serverTimeCounterHistogram *prometheus.HistogramVec
+	serverRequestInflight      *prometheus.GaugeVec
+
+	publishOpsCounter           *prometheus.CounterVec
+	publishTimeCounterSummary   *prometheus.SummaryVec
+	publishTimeCounterHistogram *prometheus.HistogramVec
+	publishMessageInflight      *prometheus.GaugeVec
+
+	subscribeOpsCounter           *prometheus.CounterVec
+	subscribeTimeCounterSummary   *prometheus.SummaryVec
+	subscribeTimeCounterHistogram *prometheus.HistogramVec
+	subscribeMessageInflight      *prometheus.GaugeVec
+	subscribeMessageSizeBytes     *prometheus.HistogramVec
+	subscribeMessageLagSeconds    *prometheus.HistogramVec
+}
+
+// NewMeter creates a Meter and registers its collectors against
+// opts.Registry (see Registry for the default).
+func NewMeter(opts ...MeterOption) *Meter {
+	options := MeterOptions{
+		HistogramBuckets:  DefaultHistogramBuckets,
+		SummaryObjectives: DefaultSummaryObjectives,
+		MetricPrefix:      DefaultMetricPrefix,
+		LabelPrefix:       DefaultLabelPrefix,
+	}
+	for _, o := range opts {
+		o(&options)
+	}
+
+	if options.Registry == nil {
+		// prometheus.DefaultRegisterer is only a *prometheus.Registry in the
+		// common case; it may be replaced with a wrapped Registerer (e.g.
+		// prometheus.WrapRegistererWithPrefix), in which case the assertion
+		// fails and we need a registry of our own rather than a nil one.
+		if reg, ok := prometheus.DefaultRegisterer.(*prometheus.Registry); ok {
+			options.Registry = reg
+		} else {
+			options.Registry = prometheus.NewRegistry()
+		}
+	}
+
+	m := &Meter{registry: options.Registry, contextLabels: options.ContextLabels}
+
+	name := func(n string) string {
+		return options.MetricPrefix + n
+	}
+	labels := func(names ...string) []string {
+		out := make([]string, 0, len(names))
+		for _, n := range names {
+			out = append(out, options.LabelPrefix+n)
+		}
+		return out
+	}
+	// baseLabels are the labels every vector carries; counterLabels also
+	// carries "status". Any configured ContextLabels are spliced in after
+	// "endpoint" and before "status" so label values line up at observe
+	// time (see Meter.contextLabelValues).
+	baseLabels := append([]string{"name", "version", "id", "endpoint"}, options.ContextLabels...)
+	counterLabels := append(append([]string{}, baseLabels...), "status")
+
+	m.clientOpsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: name("request_total"), Help: "Requests processed, partitioned by endpoint and status"},
+		labels(counterLabels...),
+	)
+	m.clientTimeCounterSummary = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{Name: name("latency_microseconds"), Help: "Request latencies in microseconds, partitioned by endpoint and status", Objectives: options.SummaryObjectives},
+		labels(counterLabels...),
+	)
+	m.clientTimeCounterHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{Name: name("request_duration_seconds"), Help: "Request time in seconds, partitioned by endpoint and status", Buckets: options.HistogramBuckets},
+		labels(counterLabels...),
+	)
+	m.clientRequestInflight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: name("client_request_inflight"), Help: "Requests currently being processed, partitioned by endpoint"},
+		labels(baseLabels...),
+	)
+
+	m.serverOpsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: name("server_request_total"), Help: "Requests processed, partitioned by endpoint and status"},
+		labels(counterLabels...),
+	)
+	m.serverTimeCounterSummary = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{Name: name("server_latency_microseconds"), Help: "Request latencies in microseconds, partitioned by endpoint and status", Objectives: options.SummaryObjectives},
+		labels(counterLabels...),
+	)
+	m.serverTimeCounterHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{Name: name("server_request_duration_seconds"), Help: "Request time in seconds, partitioned by endpoint and status", Buckets: options.HistogramBuckets},
+		labels(counterLabels...),
+	)
+	m.serverRequestInflight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: name("server_request_inflight"), Help: "Requests currently being processed, partitioned by endpoint"},
+		labels(baseLabels...),
+	)
+
+	m.publishOpsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: name("publish_message_total"), Help: "Messages sent, partitioned by endpoint and status"},
+		labels(counterLabels...),
+	)
+	m.publishTimeCounterSummary = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{Name: name("publish_message_latency_microseconds"), Help: "Message latencies in microseconds, partitioned by endpoint and status", Objectives: options.SummaryObjectives},
+		labels(counterLabels...),
+	)
+	m.publishTimeCounterHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{Name: name("publish_message_duration_seconds"), Help: "Message publish time in seconds, partitioned by endpoint and status", Buckets: options.HistogramBuckets},
+		labels(counterLabels...),
+	)
+	m.publishMessageInflight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: name("publish_message_inflight"), Help: "Messages currently being published, partitioned by endpoint"},
+		labels(baseLabels...),
+	)
+
+	m.subscribeOpsCounter = prometheus.NewCounterVec(
+		prometheus.CounterOpts{Name: name("subscribe_message_total"), Help: "Messages processed, partitioned by endpoint and status"},
+		labels(counterLabels...),
+	)
+	m.subscribeTimeCounterSummary = prometheus.NewSummaryVec(
+		prometheus.SummaryOpts{Name: name("subscribe_message_latency_microseconds"), Help: "Message processing latencies in microseconds, partitioned by endpoint and status", Objectives: options.SummaryObjectives},
+		labels(counterLabels...),
+	)
+	m.subscribeTimeCounterHistogram = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{Name: name("subscribe_message_duration_seconds"), Help: "Request time in seconds, partitioned by endpoint and status", Buckets: options.HistogramBuckets},
+		labels(counterLabels...),
+	)
+	m.subscribeMessageInflight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{Name: name("subscribe_message_inflight"), Help: "Messages currently being processed, partitioned by endpoint"},
+		labels(baseLabels...),
+	)
+	m.subscribeMessageSizeBytes = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{Name: name("subscribe_message_size_bytes"), Help: "Message body size in bytes, partitioned by endpoint", Buckets: prometheus.ExponentialBuckets(64, 4, 8)},
+		labels(baseLabels...),
+	)
+	m.subscribeMessageLagSeconds = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{Name: name("subscribe_message_lag_seconds"), Help: "Time elapsed between a message being published and received, partitioned by endpoint; may be negative under publisher/subscriber clock skew", Buckets: DefaultLagBuckets},
+		labels(baseLabels...),
+	)
+
+	m.mustRegister(
+		m.clientOpsCounter, m.clientTimeCounterSummary, m.clientTimeCounterHistogram, m.clientRequestInflight,
+		m.serverOpsCounter, m.serverTimeCounterSummary, m.serverTimeCounterHistogram, m.serverRequestInflight,
+		m.publishOpsCounter, m.publishTimeCounterSummary, m.publishTimeCounterHistogram, m.publishMessageInflight,
+		m.subscribeOpsCounter, m.subscribeTimeCounterSummary, m.subscribeTimeCounterHistogram, m.subscribeMessageInflight,
+		m.subscribeMessageSizeBytes, m.subscribeMessageLagSeconds,
+	)
+
+	return m
+}
+
+func (m *Meter) mustRegister(collectors ...prometheus.Collector) {
+	for _, collector := range collectors {
+		if err := m.registry.Register(collector); err != nil {
+			// if already registered, skip fatal
+			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
+				logger.Fatal(context.Background(), err.Error())
+			}
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package prometheus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"go.unistack.org/micro/v3/client"
+	"go.unistack.org/micro/v3/codec"
+	"go.unistack.org/micro/v3/errors"
+	"go.unistack.org/micro/v3/metadata"
+	"go.unistack.org/micro/v3/server"
+)
+
+// observeCount returns the number of observations recorded against a
+// histogram/summary Observer. testutil.ToFloat64 only supports single-value
+// collectors (counters, gauges), so sample counts are read directly off the
+// underlying dto.Metric instead.
+func observeCount(t *testing.T, o prometheus.Observer) uint64 {
+	t.Helper()
+
+	metric, ok := o.(prometheus.Metric)
+	if !ok {
+		t.Fatalf("observer %T does not implement prometheus.Metric", o)
+	}
+
+	var out dto.Metric
+	if err := metric.Write(&out); err != nil {
+		t.Fatalf("write metric: %v", err)
+	}
+
+	switch {
+	case out.GetHistogram() != nil:
+		return out.GetHistogram().GetSampleCount()
+	case out.GetSummary() != nil:
+		return out.GetSummary().GetSampleCount()
+	default:
+		t.Fatalf("observer %T is neither a histogram nor a summary", o)
+		return 0
+	}
+}
+
+type fakeRequest struct {
+	endpoint string
+}
+
+func (f *fakeRequest) Service() string           { return "Test" }
+func (f *fakeRequest) Method() string            { return f.endpoint }
+func (f *fakeRequest) Endpoint() string          { return f.endpoint }
+func (f *fakeRequest) ContentType() string       { return "application/json" }
+func (f *fakeRequest) Header() metadata.Metadata { return metadata.New(0) }
+func (f *fakeRequest) Body() interface{}         { return nil }
+func (f *fakeRequest) Read() ([]byte, error)     { return nil, nil }
+func (f *fakeRequest) Codec() codec.Codec        { return nil }
+func (f *fakeRequest) Stream() bool              { return false }
+
+type fakeMessage struct {
+	topic  string
+	body   []byte
+	header metadata.Metadata
+}
+
+func (f *fakeMessage) Topic() string             { return f.topic }
+func (f *fakeMessage) Payload() interface{}      { return nil }
+func (f *fakeMessage) ContentType() string       { return "application/json" }
+func (f *fakeMessage) Header() metadata.Metadata { return f.header }
+func (f *fakeMessage) Body() []byte              { return f.body }
+func (f *fakeMessage) Codec() codec.Codec        { return nil }
+
+type fakeClientMessage struct {
+	topic string
+	meta  metadata.Metadata
+}
+
+func (f *fakeClientMessage) Topic() string              { return f.topic }
+func (f *fakeClientMessage) Payload() interface{}       { return nil }
+func (f *fakeClientMessage) ContentType() string        { return "application/json" }
+func (f *fakeClientMessage) Metadata() metadata.Metadata { return f.meta }
+
+// fakePublishClient wraps a client.Client and overrides only Publish, so
+// tests can drive NewClientWrapper against a minimal Message/Client pair
+// instead of a full broker-backed client.
+type fakePublishClient struct {
+	client.Client
+	err error
+}
+
+func (f *fakePublishClient) Publish(ctx context.Context, msg client.Message, opts ...client.PublishOption) error {
+	return f.err
+}
+
+func TestNewMeterFallsBackWhenDefaultRegistererIsWrapped(t *testing.T) {
+	orig := prometheus.DefaultRegisterer
+	defer func() { prometheus.DefaultRegisterer = orig }()
+
+	// A common pattern (e.g. prometheus.WrapRegistererWithPrefix) replaces
+	// DefaultRegisterer with something that isn't a *prometheus.Registry.
+	prometheus.DefaultRegisterer = prometheus.WrapRegistererWithPrefix("test_", prometheus.NewRegistry())
+
+	m := NewMeter()
+	if m.registry == nil {
+		t.Fatal("NewMeter left registry nil when DefaultRegisterer was not a *prometheus.Registry")
+	}
+}
+
+func TestHandlerFuncRecordsStatusOnCounterAndDuration(t *testing.T) {
+	m := NewMeter(Registry(prometheus.NewRegistry()))
+	wrap := NewHandlerWrapper(WrapperMeter(m))
+	req := &fakeRequest{endpoint: "Foo.Bar"}
+	labelValues := []string{"", "", "", "Foo.Bar"}
+
+	ok := wrap(func(ctx context.Context, req server.Request, rsp interface{}) error { return nil })
+	if err := ok(context.Background(), req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	successLabels := append(append([]string{}, labelValues...), "success")
+	if got := testutil.ToFloat64(m.serverOpsCounter.WithLabelValues(successLabels...)); got != 1 {
+		t.Fatalf("server_request_total[success] = %v, want 1", got)
+	}
+	if got := observeCount(t, m.serverTimeCounterHistogram.WithLabelValues(successLabels...)); got != 1 {
+		t.Fatalf("server_request_duration_seconds[success] samples = %v, want 1", got)
+	}
+
+	wantErr := errors.New("test.id", "boom", 503)
+	fail := wrap(func(ctx context.Context, req server.Request, rsp interface{}) error { return wantErr })
+	if err := fail(context.Background(), req, nil); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	failLabels := append(append([]string{}, labelValues...), "503")
+	if got := testutil.ToFloat64(m.serverOpsCounter.WithLabelValues(failLabels...)); got != 1 {
+		t.Fatalf("server_request_total[503] = %v, want 1", got)
+	}
+	if got := observeCount(t, m.serverTimeCounterHistogram.WithLabelValues(failLabels...)); got != 1 {
+		t.Fatalf("server_request_duration_seconds[503] samples = %v, want 1", got)
+	}
+
+	if got := testutil.ToFloat64(m.serverRequestInflight.WithLabelValues(labelValues...)); got != 0 {
+		t.Fatalf("server_request_inflight = %v, want 0 once both calls have returned", got)
+	}
+}
+
+func TestHandlerFuncSkipsDefaultSkipEndpoints(t *testing.T) {
+	m := NewMeter(Registry(prometheus.NewRegistry()))
+	wrap := NewHandlerWrapper(WrapperMeter(m))
+
+	var called bool
+	handler := wrap(func(ctx context.Context, req server.Request, rsp interface{}) error {
+		called = true
+		return nil
+	})
+
+	req := &fakeRequest{endpoint: "Health.Live"}
+	if err := handler(context.Background(), req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !called {
+		t.Fatal("expected the wrapped handler to still run for a skipped endpoint")
+	}
+	if n := testutil.CollectAndCount(m.serverOpsCounter); n != 0 {
+		t.Fatalf("server_request_total should have no series for a skipped endpoint, got %d", n)
+	}
+	if n := testutil.CollectAndCount(m.serverRequestInflight); n != 0 {
+		t.Fatalf("server_request_inflight should have no series for a skipped endpoint, got %d", n)
+	}
+}
+
+func TestHandlerFuncAppliesContextLabels(t *testing.T) {
+	m := NewMeter(Registry(prometheus.NewRegistry()), ContextLabels("tenant"))
+	wrap := NewHandlerWrapper(WrapperMeter(m))
+	handler := wrap(func(ctx context.Context, req server.Request, rsp interface{}) error { return nil })
+
+	ctx := WithLabels(context.Background(), map[string]string{"tenant": "acme"})
+	req := &fakeRequest{endpoint: "Foo.Bar"}
+	if err := handler(ctx, req, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	labelValues := []string{"", "", "", "Foo.Bar", "acme", "success"}
+	if got := testutil.ToFloat64(m.serverOpsCounter.WithLabelValues(labelValues...)); got != 1 {
+		t.Fatalf("server_request_total with tenant label = %v, want 1", got)
+	}
+}
+
+func TestSubscriberFuncRecordsSizeAndLag(t *testing.T) {
+	m := NewMeter(Registry(prometheus.NewRegistry()))
+	wrap := NewSubscriberWrapper(WrapperMeter(m))
+	handler := wrap(func(ctx context.Context, msg server.Message) error { return nil })
+
+	header := metadata.New(1)
+	header.Set(DefaultPublishTimestampHeader, time.Now().Add(-5*time.Second).Format(time.RFC3339Nano))
+	msg := &fakeMessage{topic: "orders.created", body: []byte("hello"), header: header}
+
+	if err := handler(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	labelValues := []string{"", "", "", "orders.created"}
+	if got := observeCount(t, m.subscribeMessageSizeBytes.WithLabelValues(labelValues...)); got != 1 {
+		t.Fatalf("subscribe_message_size_bytes samples = %v, want 1", got)
+	}
+	if got := observeCount(t, m.subscribeMessageLagSeconds.WithLabelValues(labelValues...)); got != 1 {
+		t.Fatalf("subscribe_message_lag_seconds samples = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(m.subscribeMessageInflight.WithLabelValues(labelValues...)); got != 0 {
+		t.Fatalf("subscribe_message_inflight = %v, want 0 once the call has returned", got)
+	}
+
+	successLabels := append(append([]string{}, labelValues...), "success")
+	if got := testutil.ToFloat64(m.subscribeOpsCounter.WithLabelValues(successLabels...)); got != 1 {
+		t.Fatalf("subscribe_message_total[success] = %v, want 1", got)
+	}
+}
+
+func TestPublishSkipsNilMetadataInsteadOfPanicking(t *testing.T) {
+	m := NewMeter(Registry(prometheus.NewRegistry()))
+	fc := &fakePublishClient{Client: client.NewClient()}
+	wrapped := NewClientWrapper(WrapperMeter(m))(fc)
+
+	msg := &fakeClientMessage{topic: "orders.created"}
+	if err := wrapped.Publish(context.Background(), msg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	labelValues := []string{"", "", "", "orders.created", "success"}
+	if got := testutil.ToFloat64(m.publishOpsCounter.WithLabelValues(labelValues...)); got != 1 {
+		t.Fatalf("publish_message_total[success] = %v, want 1", got)
+	}
+}
@@ -3,11 +3,11 @@ package prometheus
 import (
 	"context"
 	"fmt"
-	"sync"
+	"strconv"
+	"time"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"go.unistack.org/micro/v3/client"
-	"go.unistack.org/micro/v3/logger"
+	"go.unistack.org/micro/v3/errors"
 	"go.unistack.org/micro/v3/server"
 )
 
@@ -17,30 +17,42 @@ var (
 	// default label prefix
 	DefaultLabelPrefix = "micro_"
 
-	clientOpsCounter           *prometheus.CounterVec
-	clientTimeCounterSummary   *prometheus.SummaryVec
-	clientTimeCounterHistogram *prometheus.HistogramVec
-
-	serverOpsCounter           *prometheus.CounterVec
-	serverTimeCounterSummary   *prometheus.SummaryVec
-	serverTimeCounterHistogram *prometheus.HistogramVec
+	// DefaultSkipEndpoints contains the list of endpoints that are not
+	// observed by any wrapper, to avoid polluting metrics with internal
+	// probe traffic. Client-side wrappers (CallFunc, Call, Stream) match
+	// against "<service>.<endpoint>", so these bare entries only take effect
+	// for the server handler wrapper.
+	DefaultSkipEndpoints = []string{"Meter.Metrics", "Health.Live", "Health.Ready", "Health.Version"}
+
+	// DefaultPublishTimestampHeader is the metadata key the publish wrapper
+	// stamps onto outgoing messages, and the subscribe wrapper reads back,
+	// to compute broker delivery lag.
+	DefaultPublishTimestampHeader = "Micro-Publish-Timestamp"
+)
 
-	publishOpsCounter           *prometheus.CounterVec
-	publishTimeCounterSummary   *prometheus.SummaryVec
-	publishTimeCounterHistogram *prometheus.HistogramVec
+// status returns the status label value for an observation: "success" when
+// err is nil, otherwise the underlying micro error code so histograms can be
+// sliced by response code.
+func status(err error) string {
+	if err == nil {
+		return "success"
+	}
 
-	subscribeOpsCounter           *prometheus.CounterVec
-	subscribeTimeCounterSummary   *prometheus.SummaryVec
-	subscribeTimeCounterHistogram *prometheus.HistogramVec
+	if verr := errors.FromError(err); verr != nil && verr.Code != 0 {
+		return strconv.Itoa(int(verr.Code))
+	}
 
-	mu sync.Mutex
-)
+	return "failure"
+}
 
 type Options struct {
-	Name    string
-	Version string
-	ID      string
-	Context context.Context
+	Name                   string
+	Version                string
+	ID                     string
+	Context                context.Context
+	SkipEndpoints          []string
+	Meter                  *Meter
+	PublishTimestampHeader string
 }
 
 type Option func(*Options)
@@ -51,6 +63,23 @@ func Context(ctx context.Context) Option {
 	}
 }
 
+// SkipEndpoints adds endpoints that the wrappers will not observe or
+// increment counters for, on top of DefaultSkipEndpoints.
+func SkipEndpoints(eps ...string) Option {
+	return func(o *Options) {
+		o.SkipEndpoints = append(o.SkipEndpoints, eps...)
+	}
+}
+
+func skipEndpoint(endpoints []string, endpoint string) bool {
+	for _, ep := range endpoints {
+		if ep == endpoint {
+			return true
+		}
+	}
+	return false
+}
+
 func ServiceName(name string) Option {
 	return func(o *Options) {
 		o.Name = name
@@ -69,248 +98,35 @@ func ServiceID(id string) Option {
 	}
 }
 
-func registerServerMetrics(ctx context.Context) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if serverOpsCounter == nil {
-		serverOpsCounter = prometheus.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: fmt.Sprintf("%sserver_request_total", DefaultMetricPrefix),
-				Help: "Requests processed, partitioned by endpoint and status",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "status"),
-			},
-		)
-	}
-
-	if serverTimeCounterSummary == nil {
-		serverTimeCounterSummary = prometheus.NewSummaryVec(
-			prometheus.SummaryOpts{
-				Name: fmt.Sprintf("%sserver_latency_microseconds", DefaultMetricPrefix),
-				Help: "Request latencies in microseconds, partitioned by endpoint",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-			},
-		)
-	}
-
-	if serverTimeCounterHistogram == nil {
-		serverTimeCounterHistogram = prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name: fmt.Sprintf("%sserver_request_duration_seconds", DefaultMetricPrefix),
-				Help: "Request time in seconds, partitioned by endpoint",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-			},
-		)
-	}
-
-	for _, collector := range []prometheus.Collector{serverOpsCounter, serverTimeCounterSummary, serverTimeCounterHistogram} {
-		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
-			// if already registered, skip fatal
-			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
-				logger.Fatal(ctx, err.Error())
-			}
-		}
+// WrapperMeter passes a Meter for the wrapper to record against, instead of
+// the package-level default instance.
+func WrapperMeter(m *Meter) Option {
+	return func(o *Options) {
+		o.Meter = m
 	}
-
 }
 
-func registerPublishMetrics(ctx context.Context) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if publishOpsCounter == nil {
-		publishOpsCounter = prometheus.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: fmt.Sprintf("%spublish_message_total", DefaultMetricPrefix),
-				Help: "Messages sent, partitioned by endpoint and status",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "status"),
-			},
-		)
-	}
-
-	if publishTimeCounterSummary == nil {
-		publishTimeCounterSummary = prometheus.NewSummaryVec(
-			prometheus.SummaryOpts{
-				Name: fmt.Sprintf("%spublish_message_latency_microseconds", DefaultMetricPrefix),
-				Help: "Message latencies in microseconds, partitioned by endpoint",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-			},
-		)
-	}
-
-	if publishTimeCounterHistogram == nil {
-		publishTimeCounterHistogram = prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name: fmt.Sprintf("%spublish_message_duration_seconds", DefaultMetricPrefix),
-				Help: "Message publish time in seconds, partitioned by endpoint",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-			},
-		)
-	}
-
-	for _, collector := range []prometheus.Collector{publishOpsCounter, publishTimeCounterSummary, publishTimeCounterHistogram} {
-		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
-			// if already registered, skip fatal
-			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
-				logger.Fatal(ctx, err.Error())
-			}
-		}
+// PublishTimestampHeader overrides the metadata key used to stamp and read
+// back the publish time, in place of DefaultPublishTimestampHeader.
+func PublishTimestampHeader(header string) Option {
+	return func(o *Options) {
+		o.PublishTimestampHeader = header
 	}
-
 }
 
-func registerSubscribeMetrics(ctx context.Context) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if subscribeOpsCounter == nil {
-		subscribeOpsCounter = prometheus.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: fmt.Sprintf("%ssubscribe_message_total", DefaultMetricPrefix),
-				Help: "Messages processed, partitioned by endpoint and status",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "status"),
-			},
-		)
+func newOptions(opts ...Option) Options {
+	options := Options{
+		Context:                context.Background(),
+		SkipEndpoints:          DefaultSkipEndpoints,
+		PublishTimestampHeader: DefaultPublishTimestampHeader,
 	}
-
-	if subscribeTimeCounterSummary == nil {
-		subscribeTimeCounterSummary = prometheus.NewSummaryVec(
-			prometheus.SummaryOpts{
-				Name: fmt.Sprintf("%ssubscribe_message_latency_microseconds", DefaultMetricPrefix),
-				Help: "Message processing latencies in microseconds, partitioned by endpoint",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-			},
-		)
+	for _, o := range opts {
+		o(&options)
 	}
-
-	if subscribeTimeCounterHistogram == nil {
-		subscribeTimeCounterHistogram = prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name: fmt.Sprintf("%ssubscribe_message_duration_seconds", DefaultMetricPrefix),
-				Help: "Request time in seconds, partitioned by endpoint",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-			},
-		)
+	if options.Meter == nil {
+		options.Meter = defaultMeterInstance()
 	}
-
-	for _, collector := range []prometheus.Collector{subscribeOpsCounter, subscribeTimeCounterSummary, subscribeTimeCounterHistogram} {
-		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
-			// if already registered, skip fatal
-			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
-				logger.Fatal(ctx, err.Error())
-			}
-		}
-	}
-
-}
-
-func registerClientMetrics(ctx context.Context) {
-	mu.Lock()
-	defer mu.Unlock()
-
-	if clientOpsCounter == nil {
-		clientOpsCounter = prometheus.NewCounterVec(
-			prometheus.CounterOpts{
-				Name: fmt.Sprintf("%srequest_total", DefaultMetricPrefix),
-				Help: "Requests processed, partitioned by endpoint and status",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "status"),
-			},
-		)
-	}
-
-	if clientTimeCounterSummary == nil {
-		clientTimeCounterSummary = prometheus.NewSummaryVec(
-			prometheus.SummaryOpts{
-				Name: fmt.Sprintf("%slatency_microseconds", DefaultMetricPrefix),
-				Help: "Request latencies in microseconds, partitioned by endpoint",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-			},
-		)
-	}
-
-	if clientTimeCounterHistogram == nil {
-		clientTimeCounterHistogram = prometheus.NewHistogramVec(
-			prometheus.HistogramOpts{
-				Name: fmt.Sprintf("%srequest_duration_seconds", DefaultMetricPrefix),
-				Help: "Request time in seconds, partitioned by endpoint",
-			},
-			[]string{
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "name"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "version"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "id"),
-				fmt.Sprintf("%s%s", DefaultLabelPrefix, "endpoint"),
-			},
-		)
-	}
-
-	for _, collector := range []prometheus.Collector{clientOpsCounter, clientTimeCounterSummary, clientTimeCounterHistogram} {
-		if err := prometheus.DefaultRegisterer.Register(collector); err != nil {
-			// if already registered, skip fatal
-			if _, ok := err.(prometheus.AlreadyRegisteredError); !ok {
-				logger.Fatal(ctx, err.Error())
-			}
-		}
-	}
-
+	return options
 }
 
 type wrapper struct {
@@ -319,14 +135,16 @@ type wrapper struct {
 	client.Client
 }
 
-func NewClientWrapper(opts ...Option) client.Wrapper {
-	options := Options{Context: context.Background()}
-	for _, o := range opts {
-		o(&options)
-	}
+// labelValues returns the name/version/id/endpoint label values for this
+// wrapper, followed by the Meter's configured ContextLabels resolved from
+// ctx (see WithLabels and Meter.contextLabelValues).
+func (w *wrapper) labelValues(ctx context.Context, endpoint string) []string {
+	values := []string{w.options.Name, w.options.Version, w.options.ID, endpoint}
+	return append(values, w.options.Meter.contextLabelValues(ctx)...)
+}
 
-	registerClientMetrics(options.Context)
-	registerPublishMetrics(options.Context)
+func NewClientWrapper(opts ...Option) client.Wrapper {
+	options := newOptions(opts...)
 
 	return func(c client.Client) client.Client {
 		handler := &wrapper{
@@ -339,12 +157,7 @@ func NewClientWrapper(opts ...Option) client.Wrapper {
 }
 
 func NewCallWrapper(opts ...Option) client.CallWrapper {
-	options := Options{Context: context.Background()}
-	for _, o := range opts {
-		o(&options)
-	}
-
-	registerClientMetrics(options.Context)
+	options := newOptions(opts...)
 
 	return func(fn client.CallFunc) client.CallFunc {
 		handler := &wrapper{
@@ -358,91 +171,114 @@ func NewCallWrapper(opts ...Option) client.CallWrapper {
 
 func (w *wrapper) CallFunc(ctx context.Context, addr string, req client.Request, rsp interface{}, opts client.CallOptions) error {
 	endpoint := fmt.Sprintf("%s.%s", req.Service(), req.Endpoint())
+	if skipEndpoint(w.options.SkipEndpoints, endpoint) {
+		return w.callFunc(ctx, addr, req, rsp, opts)
+	}
+
+	m := w.options.Meter
+	labelValues := w.labelValues(ctx, endpoint)
 
-	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
-		us := v * 1000000 // make microseconds
-		clientTimeCounterSummary.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(us)
-		clientTimeCounterHistogram.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(v)
-	}))
-	defer timer.ObserveDuration()
+	inflight := m.clientRequestInflight.WithLabelValues(labelValues...)
+	inflight.Inc()
+	defer inflight.Dec()
 
+	start := time.Now()
 	err := w.callFunc(ctx, addr, req, rsp, opts)
-	if err == nil {
-		clientOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "success").Inc()
-	} else {
-		clientOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "failure").Inc()
-	}
+	elapsed := time.Since(start)
 
-	return err
+	statusLabelValues := append(labelValues, status(err))
+	m.clientOpsCounter.WithLabelValues(statusLabelValues...).Inc()
+	m.clientTimeCounterSummary.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds() * 1000000)
+	m.clientTimeCounterHistogram.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds())
 
+	return err
 }
 
 func (w *wrapper) Call(ctx context.Context, req client.Request, rsp interface{}, opts ...client.CallOption) error {
 	endpoint := fmt.Sprintf("%s.%s", req.Service(), req.Endpoint())
+	if skipEndpoint(w.options.SkipEndpoints, endpoint) {
+		return w.Client.Call(ctx, req, rsp, opts...)
+	}
+
+	m := w.options.Meter
+	labelValues := w.labelValues(ctx, endpoint)
 
-	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
-		us := v * 1000000 // make microseconds
-		clientTimeCounterSummary.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(us)
-		clientTimeCounterHistogram.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(v)
-	}))
-	defer timer.ObserveDuration()
+	inflight := m.clientRequestInflight.WithLabelValues(labelValues...)
+	inflight.Inc()
+	defer inflight.Dec()
 
+	start := time.Now()
 	err := w.Client.Call(ctx, req, rsp, opts...)
-	if err == nil {
-		clientOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "success").Inc()
-	} else {
-		clientOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "failure").Inc()
-	}
+	elapsed := time.Since(start)
+
+	statusLabelValues := append(labelValues, status(err))
+	m.clientOpsCounter.WithLabelValues(statusLabelValues...).Inc()
+	m.clientTimeCounterSummary.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds() * 1000000)
+	m.clientTimeCounterHistogram.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds())
 
 	return err
 }
 
 func (w *wrapper) Stream(ctx context.Context, req client.Request, opts ...client.CallOption) (client.Stream, error) {
 	endpoint := fmt.Sprintf("%s.%s", req.Service(), req.Endpoint())
+	if skipEndpoint(w.options.SkipEndpoints, endpoint) {
+		return w.Client.Stream(ctx, req, opts...)
+	}
 
-	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
-		us := v * 1000000 // make microseconds
-		clientTimeCounterSummary.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(us)
-		clientTimeCounterHistogram.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(v)
-	}))
-	defer timer.ObserveDuration()
+	m := w.options.Meter
+	labelValues := w.labelValues(ctx, endpoint)
 
+	inflight := m.clientRequestInflight.WithLabelValues(labelValues...)
+	inflight.Inc()
+	defer inflight.Dec()
+
+	start := time.Now()
 	stream, err := w.Client.Stream(ctx, req, opts...)
-	if err == nil {
-		clientOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "success").Inc()
-	} else {
-		clientOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "failure").Inc()
-	}
+	elapsed := time.Since(start)
+
+	statusLabelValues := append(labelValues, status(err))
+	m.clientOpsCounter.WithLabelValues(statusLabelValues...).Inc()
+	m.clientTimeCounterSummary.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds() * 1000000)
+	m.clientTimeCounterHistogram.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds())
 
 	return stream, err
 }
 
 func (w *wrapper) Publish(ctx context.Context, p client.Message, opts ...client.PublishOption) error {
 	endpoint := p.Topic()
+	if skipEndpoint(w.options.SkipEndpoints, endpoint) {
+		return w.Client.Publish(ctx, p, opts...)
+	}
+
+	// Message.Metadata() may return nil for a custom broker Message
+	// implementation (the stock client always initializes it via
+	// metadata.New), and Metadata.Set on a nil map panics, so skip the stamp
+	// rather than risk publish failing just to stamp a metric.
+	if md := p.Metadata(); md != nil {
+		md.Set(w.options.PublishTimestampHeader, time.Now().Format(time.RFC3339Nano))
+	}
 
-	timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
-		us := v * 1000000 // make microseconds
-		publishTimeCounterSummary.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(us)
-		publishTimeCounterHistogram.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(v)
-	}))
-	defer timer.ObserveDuration()
+	m := w.options.Meter
+	labelValues := w.labelValues(ctx, endpoint)
 
+	inflight := m.publishMessageInflight.WithLabelValues(labelValues...)
+	inflight.Inc()
+	defer inflight.Dec()
+
+	start := time.Now()
 	err := w.Client.Publish(ctx, p, opts...)
-	if err == nil {
-		publishOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "success").Inc()
-	} else {
-		publishOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "failure").Inc()
-	}
+	elapsed := time.Since(start)
+
+	statusLabelValues := append(labelValues, status(err))
+	m.publishOpsCounter.WithLabelValues(statusLabelValues...).Inc()
+	m.publishTimeCounterSummary.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds() * 1000000)
+	m.publishTimeCounterHistogram.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds())
 
 	return err
 }
 
 func NewHandlerWrapper(opts ...Option) server.HandlerWrapper {
-	options := Options{Context: context.Background()}
-	for _, o := range opts {
-		o(&options)
-	}
-	registerServerMetrics(options.Context)
+	options := newOptions(opts...)
 
 	handler := &wrapper{
 		options: options,
@@ -454,32 +290,32 @@ func NewHandlerWrapper(opts ...Option) server.HandlerWrapper {
 func (w *wrapper) HandlerFunc(fn server.HandlerFunc) server.HandlerFunc {
 	return func(ctx context.Context, req server.Request, rsp interface{}) error {
 		endpoint := req.Endpoint()
+		if skipEndpoint(w.options.SkipEndpoints, endpoint) {
+			return fn(ctx, req, rsp)
+		}
+
+		m := w.options.Meter
+		labelValues := w.labelValues(ctx, endpoint)
 
-		timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
-			us := v * 1000000 // make microseconds
-			serverTimeCounterSummary.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(us)
-			serverTimeCounterHistogram.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(v)
-		}))
-		defer timer.ObserveDuration()
+		inflight := m.serverRequestInflight.WithLabelValues(labelValues...)
+		inflight.Inc()
+		defer inflight.Dec()
 
+		start := time.Now()
 		err := fn(ctx, req, rsp)
-		if err == nil {
-			serverOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "success").Inc()
-		} else {
-			serverOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "failure").Inc()
-		}
+		elapsed := time.Since(start)
+
+		statusLabelValues := append(labelValues, status(err))
+		m.serverOpsCounter.WithLabelValues(statusLabelValues...).Inc()
+		m.serverTimeCounterSummary.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds() * 1000000)
+		m.serverTimeCounterHistogram.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds())
 
 		return err
 	}
 }
 
 func NewSubscriberWrapper(opts ...Option) server.SubscriberWrapper {
-	options := Options{Context: context.Background()}
-	for _, o := range opts {
-		o(&options)
-	}
-
-	registerSubscribeMetrics(options.Context)
+	options := newOptions(opts...)
 
 	handler := &wrapper{
 		options: options,
@@ -491,21 +327,35 @@ func NewSubscriberWrapper(opts ...Option) server.SubscriberWrapper {
 func (w *wrapper) SubscriberFunc(fn server.SubscriberFunc) server.SubscriberFunc {
 	return func(ctx context.Context, msg server.Message) error {
 		endpoint := msg.Topic()
+		if skipEndpoint(w.options.SkipEndpoints, endpoint) {
+			return fn(ctx, msg)
+		}
 
-		timer := prometheus.NewTimer(prometheus.ObserverFunc(func(v float64) {
-			us := v * 1000000 // make microseconds
-			subscribeTimeCounterSummary.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(us)
-			subscribeTimeCounterHistogram.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint).Observe(v)
-		}))
-		defer timer.ObserveDuration()
+		m := w.options.Meter
+		labelValues := w.labelValues(ctx, endpoint)
 
-		err := fn(ctx, msg)
-		if err == nil {
-			subscribeOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "success").Inc()
-		} else {
-			subscribeOpsCounter.WithLabelValues(w.options.Name, w.options.Version, w.options.ID, endpoint, "failure").Inc()
+		inflight := m.subscribeMessageInflight.WithLabelValues(labelValues...)
+		inflight.Inc()
+		defer inflight.Dec()
+
+		m.subscribeMessageSizeBytes.WithLabelValues(labelValues...).Observe(float64(len(msg.Body())))
+		if raw, ok := msg.Header().Get(w.options.PublishTimestampHeader); ok {
+			if ts, terr := time.Parse(time.RFC3339Nano, raw); terr == nil {
+				// May be negative if the subscriber's clock trails the
+				// publisher's; see DefaultLagBuckets.
+				m.subscribeMessageLagSeconds.WithLabelValues(labelValues...).Observe(time.Since(ts).Seconds())
+			}
 		}
 
+		start := time.Now()
+		err := fn(ctx, msg)
+		elapsed := time.Since(start)
+
+		statusLabelValues := append(labelValues, status(err))
+		m.subscribeOpsCounter.WithLabelValues(statusLabelValues...).Inc()
+		m.subscribeTimeCounterSummary.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds() * 1000000)
+		m.subscribeTimeCounterHistogram.WithLabelValues(statusLabelValues...).Observe(elapsed.Seconds())
+
 		return err
 	}
 }